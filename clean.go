@@ -0,0 +1,202 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Clean normalizes a URL path the way path.Clean normalizes a filesystem
+// path: it collapses repeated "/", eliminates "." elements, resolves inner
+// ".." elements against the preceding element, and drops any ".." that
+// would escape the root. Unlike path.Clean it always returns a rooted path.
+func Clean(p string) string {
+	if p == "" {
+		return pathDelimiter
+	}
+
+	n := len(p)
+	var buf []byte
+
+	r := 1
+	w := 1
+
+	if p[0] != '/' {
+		r = 0
+		buf = make([]byte, n+1)
+		buf[0] = '/'
+	}
+
+	trailing := n > 1 && p[n-1] == '/'
+
+	for r < n {
+		switch {
+		case p[r] == '/':
+			r++
+
+		case p[r] == '.' && r+1 == n:
+			trailing = true
+			r++
+
+		case p[r] == '.' && p[r+1] == '/':
+			r += 2
+
+		case p[r] == '.' && p[r+1] == '.' && (r+2 == n || p[r+2] == '/'):
+			r += 3
+
+			if w > 1 {
+				w--
+
+				if buf == nil {
+					for w > 1 && p[w] != '/' {
+						w--
+					}
+				} else {
+					for w > 1 && buf[w] != '/' {
+						w--
+					}
+				}
+			}
+
+		default:
+			if w > 1 {
+				appendByte(&buf, p, w, '/')
+				w++
+			}
+
+			for r < n && p[r] != '/' {
+				appendByte(&buf, p, w, p[r])
+				w++
+				r++
+			}
+		}
+	}
+
+	if trailing && w > 1 {
+		appendByte(&buf, p, w, '/')
+		w++
+	}
+
+	if buf == nil {
+		return p[:w]
+	}
+	return string(buf[:w])
+}
+
+// appendByte lazily allocates buf on first divergence from s, mirroring a
+// copy-on-write scan so the common already-clean path costs nothing.
+func appendByte(buf *[]byte, s string, w int, c byte) {
+	b := *buf
+	if b == nil {
+		if s[w] == c {
+			return
+		}
+		b = make([]byte, len(s))
+		copy(b, s[:w])
+		*buf = b
+	}
+	b[w] = c
+}
+
+// cleanPath is the normalized form routes are matched against: Clean, with
+// any trailing slash (besides the bare root) trimmed off.
+func cleanPath(path string) string {
+	p := Clean(path)
+	if len(p) > 1 {
+		p = strings.TrimRight(p, pathDelimiter)
+		if p == "" {
+			p = pathDelimiter
+		}
+	}
+	return p
+}
+
+// trailingSlashMismatch reports whether raw and canonical refer to the same
+// route and differ only by a trailing slash.
+func trailingSlashMismatch(raw, canonical string) bool {
+	if raw == canonical {
+		return false
+	}
+	return strings.TrimSuffix(raw, pathDelimiter) == canonical || raw+pathDelimiter == canonical
+}
+
+// redirectStatus picks 301 for GET/HEAD (no body to preserve) and 308 for
+// any other method, so the client is forced to repeat the same method and
+// body against the canonical path rather than silently switching to GET.
+func redirectStatus(method string) int {
+	if method == http.MethodGet || method == http.MethodHead {
+		return http.StatusMovedPermanently
+	}
+	return http.StatusPermanentRedirect
+}
+
+func redirectTo(w http.ResponseWriter, request *http.Request, path string) {
+	url := *request.URL
+	url.Path = path
+	http.Redirect(w, request, url.String(), redirectStatus(request.Method))
+}
+
+// findCaseInsensitive walks the method's trie matching static segments
+// case-insensitively, and returns the canonically-cased path if a handler
+// is found at the end of the walk.
+func (r *Router) findCaseInsensitive(method, path string) (string, bool) {
+	root, ok := r.trees[method]
+	if !ok {
+		return "", false
+	}
+
+	canonical, ok := root.matchCaseInsensitiveFrom(splitPath(path), 0)
+	if !ok {
+		return "", false
+	}
+	return pathDelimiter + strings.Join(canonical, pathDelimiter), true
+}
+
+// matchCaseInsensitiveFrom mirrors matchFrom's priority and backtracking,
+// but ignores case on static segments and matches constrained segments
+// case-sensitively (they are patterns, not literal casing); the param and
+// catch-all values can't be case-corrected, so they're passed through
+// unchanged. It returns the canonically-cased segments from i onward.
+func (n *node) matchCaseInsensitiveFrom(segments []string, i int) ([]string, bool) {
+	for i < len(segments) && segments[i] == "" {
+		i++
+	}
+
+	if i >= len(segments) {
+		if n.handler != nil {
+			return []string{}, true
+		}
+		return nil, false
+	}
+
+	seg := segments[i]
+
+	for candidate, child := range n.static {
+		if !strings.EqualFold(candidate, seg) {
+			continue
+		}
+		if rest, ok := child.matchCaseInsensitiveFrom(segments, i+1); ok {
+			return append([]string{candidate}, rest...), true
+		}
+	}
+
+	for _, cc := range n.constrained {
+		if !cc.pattern.MatchString(seg) {
+			continue
+		}
+		if rest, ok := cc.child.matchCaseInsensitiveFrom(segments, i+1); ok {
+			return append([]string{seg}, rest...), true
+		}
+	}
+
+	if n.paramChild != nil {
+		if rest, ok := n.paramChild.matchCaseInsensitiveFrom(segments, i+1); ok {
+			return append([]string{seg}, rest...), true
+		}
+	}
+
+	if n.catchAll != nil && n.catchAll.handler != nil {
+		return []string{strings.Join(segments[i:], pathDelimiter)}, true
+	}
+
+	return nil, false
+}
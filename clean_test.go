@@ -0,0 +1,110 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClean(t *testing.T) {
+	cases := []struct {
+		in       string
+		expected string
+	}{
+		{"", "/"},
+		{"/", "/"},
+		{"/foo", "/foo"},
+		{"//foo", "/foo"},
+		{"/foo/", "/foo/"},
+		{"/foo//bar", "/foo/bar"},
+		{"/foo/./bar", "/foo/bar"},
+		{"/foo/../bar", "/bar"},
+		{"/foo/bar/..", "/foo"},
+		{"/../foo", "/foo"},
+		{"foo", "/foo"},
+	}
+
+	for _, c := range cases {
+		if got := Clean(c.in); got != c.expected {
+			t.Errorf("Clean(%q) = %q, expected %q", c.in, got, c.expected)
+		}
+	}
+}
+
+func TestRedirectTrailingSlash(t *testing.T) {
+	router := NewRouter()
+	router.RedirectTrailingSlash = true
+	router.GET("/widgets", noopHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/widgets/", nil))
+
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("expected 301, got %d", w.Code)
+	}
+	if loc := w.Header().Get("Location"); loc != "/widgets" {
+		t.Fatalf("expected redirect to /widgets, got %q", loc)
+	}
+}
+
+func TestRedirectTrailingSlashPreservesNonGetMethodWith308(t *testing.T) {
+	router := NewRouter()
+	router.RedirectTrailingSlash = true
+	router.POST("/widgets", noopHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/widgets/", nil))
+
+	if w.Code != http.StatusPermanentRedirect {
+		t.Fatalf("expected 308, got %d", w.Code)
+	}
+}
+
+func TestRedirectFixedPath(t *testing.T) {
+	router := NewRouter()
+	router.RedirectFixedPath = true
+	router.GET("/Users", noopHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("expected 301, got %d", w.Code)
+	}
+	if loc := w.Header().Get("Location"); loc != "/Users" {
+		t.Fatalf("expected redirect to /Users, got %q", loc)
+	}
+}
+
+func TestRedirectFixedPathMatchesConstrainedSegments(t *testing.T) {
+	router := NewRouter()
+	router.RedirectFixedPath = true
+	router.GET("/Users/{id:int}", noopHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("expected 301, got %d", w.Code)
+	}
+	if loc := w.Header().Get("Location"); loc != "/Users/42" {
+		t.Fatalf("expected redirect to /Users/42, got %q", loc)
+	}
+}
+
+func TestRedirectFixedPathBacktracksPastStaticDeadEnd(t *testing.T) {
+	router := NewRouter()
+	router.RedirectFixedPath = true
+	router.GET("/Users/Admin", noopHandler)
+	router.GET("/Users/:id/Posts", noopHandler)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/admin/posts", nil))
+
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("expected 301, got %d", w.Code)
+	}
+	if loc := w.Header().Get("Location"); loc != "/Users/admin/Posts" {
+		t.Fatalf("expected redirect to /Users/admin/Posts, got %q", loc)
+	}
+}
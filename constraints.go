@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ErrParamNotFound happens when ParamInt is asked for a parameter that was
+// not captured for the matched route.
+var ErrParamNotFound = errors.New("parameter not found")
+
+const (
+	patternInt  = `[0-9]+`
+	patternUUID = `[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`
+)
+
+// shorthands maps the common constraint names to their pre-baked regex.
+var shorthands = map[string]string{
+	"int":  patternInt,
+	"uuid": patternUUID,
+}
+
+// placeholder matches a single `{name:pattern}` constraint within a segment.
+var placeholder = regexp.MustCompile(`\{(\w+):([^{}]+)\}`)
+
+// compileConstrainedSegment turns a segment such as
+// `{name:[a-z]+}.{ext:jpg|png}` into a single anchored regex with one named
+// capture group per placeholder, substituting the `int`/`uuid` shorthands
+// where used. Literal runs between placeholders are matched verbatim.
+func compileConstrainedSegment(seg string) (*regexp.Regexp, []string, error) {
+	matches := placeholder.FindAllStringSubmatchIndex(seg, -1)
+
+	var sb strings.Builder
+	var names []string
+
+	sb.WriteString("^")
+	last := 0
+	for _, m := range matches {
+		sb.WriteString(regexp.QuoteMeta(seg[last:m[0]]))
+
+		name := seg[m[2]:m[3]]
+		pattern := seg[m[4]:m[5]]
+		if expanded, ok := shorthands[pattern]; ok {
+			pattern = expanded
+		}
+
+		names = append(names, name)
+		sb.WriteString("(?P<" + name + ">" + pattern + ")")
+		last = m[1]
+	}
+	sb.WriteString(regexp.QuoteMeta(seg[last:]))
+	sb.WriteString("$")
+
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	return re, names, nil
+}
+
+// ParamInt reads the named route parameter from the request and parses it
+// as a base-10 int64, as a convenience for routes constrained with
+// `{name:int}` or a plain `:name`.
+func ParamInt(r *http.Request, name string) (int64, error) {
+	params, ok := r.Context().Value(ParametersKey).(map[string]string)
+	if !ok {
+		return 0, ErrParamNotFound
+	}
+
+	value, ok := params[name]
+	if !ok {
+		return 0, ErrParamNotFound
+	}
+
+	return strconv.ParseInt(value, 10, 64)
+}
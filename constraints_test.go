@@ -0,0 +1,84 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestConstrainedSegmentMatchesAndCaptures(t *testing.T) {
+	router := NewRouter()
+	router.GET("/users/{id:int}", func(w http.ResponseWriter, r *http.Request) {
+		id, err := ParamInt(r, "id")
+		if err != nil {
+			t.Fatalf("unexpected error from ParamInt: %v", err)
+		}
+		if id != 42 {
+			t.Fatalf("expected id 42, got %d", id)
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
+
+func TestConstrainedSegmentFallsThroughOnMismatch(t *testing.T) {
+	router := NewRouter()
+	router.GET("/users/{id:int}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("int"))
+	})
+	router.GET("/users/:name", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("name"))
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/bob", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if w.Body.String() != "name" {
+		t.Fatalf("expected the non-int route to handle a non-numeric id, got %q", w.Body.String())
+	}
+}
+
+func TestConstrainedSegmentWithMultiplePlaceholders(t *testing.T) {
+	router := NewRouter()
+	var ext, name string
+	router.GET("/files/{name:[a-z]+}.{ext:jpg|png}", func(w http.ResponseWriter, r *http.Request) {
+		params := r.Context().Value(ParametersKey).(map[string]string)
+		name = params["name"]
+		ext = params["ext"]
+		w.WriteHeader(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/files/photo.png", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if name != "photo" || ext != "png" {
+		t.Fatalf("expected name=photo ext=png, got name=%q ext=%q", name, ext)
+	}
+}
+
+func TestParamIntErrorsOnNonNumericValue(t *testing.T) {
+	router := NewRouter()
+	router.GET("/users/:id", func(w http.ResponseWriter, r *http.Request) {
+		if _, err := ParamInt(r, "id"); err == nil {
+			t.Fatal("expected an error parsing a non-numeric id")
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/bob", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
@@ -0,0 +1,38 @@
+package main
+
+import "net/http"
+
+// Use appends middleware to the router's chain. Middleware is applied in
+// registration order, wrapping the final handler from outside in, and only
+// affects routes registered after the call (matching chi's semantics).
+func (r *Router) Use(middleware ...func(http.Handler) http.Handler) {
+	r.middleware = append(r.middleware, middleware...)
+}
+
+// Group creates a sub-router sharing the parent's route trees but scoped to
+// prefix and the parent's middleware chain at the time of the call. Routes
+// registered inside fn are prefixed accordingly, and Use calls inside fn
+// only apply within the group. Groups can be nested.
+func (r *Router) Group(prefix string, fn func(r *Router)) {
+	if r.trees == nil {
+		r.trees = make(map[string]*node)
+	}
+
+	sub := &Router{
+		trees:      r.trees,
+		logger:     r.logger,
+		prefix:     r.prefix + prefix,
+		middleware: append([]func(http.Handler) http.Handler{}, r.middleware...),
+	}
+	fn(sub)
+}
+
+// wrapMiddleware composes handler with middleware, the first entry becoming
+// the outermost wrapper.
+func wrapMiddleware(handler http.HandlerFunc, middleware []func(http.Handler) http.Handler) http.HandlerFunc {
+	var h http.Handler = handler
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	return h.ServeHTTP
+}
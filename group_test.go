@@ -0,0 +1,105 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGroupPrefixesRegisteredRoutes(t *testing.T) {
+	router := NewRouter()
+	router.Group("/v1", func(r *Router) {
+		r.GET("/widgets", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v1/widgets", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
+
+func TestGroupNesting(t *testing.T) {
+	router := NewRouter()
+	router.Group("/v1", func(r *Router) {
+		r.Group("/admin", func(r *Router) {
+			r.GET("/widgets", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+		})
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/v1/admin/widgets", nil)
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
+
+func TestUseOnlyAppliesToRoutesRegisteredAfter(t *testing.T) {
+	router := NewRouter()
+	var order []string
+
+	router.GET("/before", func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "before-handler")
+	})
+
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			order = append(order, "middleware")
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	router.GET("/after", func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "after-handler")
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/before", nil))
+	if len(order) != 1 || order[0] != "before-handler" {
+		t.Fatalf("expected the pre-Use route to run unwrapped, got %v", order)
+	}
+
+	order = nil
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/after", nil))
+	if len(order) != 2 || order[0] != "middleware" || order[1] != "after-handler" {
+		t.Fatalf("expected the post-Use route to run through the middleware first, got %v", order)
+	}
+}
+
+func TestMiddlewareComposesInRegistrationOrder(t *testing.T) {
+	router := NewRouter()
+	var order []string
+
+	mw := func(name string) func(http.Handler) http.Handler {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	router.Use(mw("first"), mw("second"))
+	router.GET("/widgets", func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/widgets", nil))
+
+	expected := []string{"first", "second", "handler"}
+	if len(order) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, order)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, order)
+		}
+	}
+}
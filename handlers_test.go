@@ -0,0 +1,76 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCustomNotFoundHandler(t *testing.T) {
+	router := NewRouter()
+	router.NotFound = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	if w.Code != http.StatusTeapot {
+		t.Fatalf("expected custom NotFound handler to run, got %d", w.Code)
+	}
+}
+
+func TestCustomMethodNotAllowedHandler(t *testing.T) {
+	router := NewRouter()
+	router.GET("/widgets", noopHandler)
+	router.MethodNotAllowed = func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/widgets", nil))
+
+	if w.Code != http.StatusTeapot {
+		t.Fatalf("expected custom MethodNotAllowed handler to run, got %d", w.Code)
+	}
+	if allow := w.Header().Get("Allow"); allow != http.MethodGet {
+		t.Fatalf("expected Allow: GET, got %q", allow)
+	}
+}
+
+func TestPanicHandlerRecoversDispatchedHandlerPanics(t *testing.T) {
+	router := NewRouter()
+	var recovered interface{}
+	router.PanicHandler = func(w http.ResponseWriter, r *http.Request, rec interface{}) {
+		recovered = rec
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	router.GET("/boom", func(w http.ResponseWriter, r *http.Request) {
+		panic("kaboom")
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/boom", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("expected 500 from PanicHandler, got %d", w.Code)
+	}
+	if recovered != "kaboom" {
+		t.Fatalf("expected PanicHandler to receive the recovered value, got %v", recovered)
+	}
+}
+
+func TestNoPanicHandlerLetsPanicPropagate(t *testing.T) {
+	router := NewRouter()
+	router.GET("/boom", func(w http.ResponseWriter, r *http.Request) {
+		panic("kaboom")
+	})
+
+	defer func() {
+		if rec := recover(); rec == nil {
+			t.Fatal("expected the panic to propagate with no PanicHandler installed")
+		}
+	}()
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/boom", nil))
+}
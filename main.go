@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log"
 	"net/http"
+	"sort"
 	"strings"
 )
 
@@ -21,48 +22,99 @@ var (
 	ErrNoHandlerFound = errors.New("no http handler found")
 	// ErrPathAlreadyRegistered happens when the path given is already registered
 	ErrPathAlreadyRegistered = errors.New("path already registered")
+	// ErrConflictingWildcard happens when a `:name` or `*name` segment is registered
+	// with a different name than the wildcard already present at that position
+	ErrConflictingWildcard = errors.New("conflicting wildcard name for existing route segment")
+	// ErrCatchAllNotLast happens when a `*name` segment is not the last segment of the pattern
+	ErrCatchAllNotLast = errors.New("catch-all segment must be the last segment of the pattern")
 
-	errRuleDoesNotMatch        = errors.New("routing rule does not match given request")
-	errMethodMismatch          = errors.New("method mismatch")
-	errPathMismatch            = errors.New("path mismatch")
 	errPathMatchMethodMismatch = errors.New("path match, method mismatch")
 )
 
 // NewRouter returns a new router instance
 func NewRouter() Router {
 	return Router{
-		routes: []routingRule{},
+		trees: make(map[string]*node),
 	}
 }
 
-type routingRule struct {
-	Pattern string
-	Method  string
-	Handler http.HandlerFunc
-}
-
 // Router is the main routing component
 type Router struct {
-	routes []routingRule
-	logger *log.Logger
+	trees      map[string]*node
+	logger     *log.Logger
+	prefix     string
+	middleware []func(http.Handler) http.Handler
+
+	// RedirectTrailingSlash issues a redirect to the canonical path when a
+	// request differs from a registered route only by a trailing slash.
+	RedirectTrailingSlash bool
+	// RedirectFixedPath issues a redirect to the canonically-cased path
+	// when a case-insensitive match is found for an otherwise unmatched path.
+	RedirectFixedPath bool
+
+	// NotFound, if set, handles requests that match no route instead of the
+	// default bare 404.
+	NotFound http.HandlerFunc
+	// MethodNotAllowed, if set, handles requests whose path matches but
+	// whose method does not, instead of the default bare 405.
+	MethodNotAllowed http.HandlerFunc
+	// PanicHandler, if set, is called with the recovered value whenever a
+	// dispatched handler panics, instead of letting the panic propagate.
+	PanicHandler func(http.ResponseWriter, *http.Request, interface{})
 }
 
 func (r Router) ServeHTTP(w http.ResponseWriter, request *http.Request) {
+	rawPath := request.URL.Path
+	canonicalPath := cleanPath(rawPath)
+
 	handler, matches, err := r.determineHandler(request)
 	if err == nil {
+		if r.RedirectTrailingSlash && trailingSlashMismatch(rawPath, canonicalPath) {
+			redirectTo(w, request, canonicalPath)
+			return
+		}
+
 		ctx := context.WithValue(request.Context(), ParametersKey, matches)
 		request = request.WithContext(ctx)
 
-		handler(w, request)
+		r.dispatch(handler, w, request)
 		return
 	}
 
+	if err == ErrNoHandlerFound && r.RedirectFixedPath {
+		if fixedPath, ok := r.findCaseInsensitive(request.Method, canonicalPath); ok {
+			redirectTo(w, request, fixedPath)
+			return
+		}
+	}
+
+	segments := splitPath(canonicalPath)
+
+	if request.Method == http.MethodOptions {
+		if allowed := r.allowedMethods(segments); len(allowed) > 0 {
+			w.Header().Set("Allow", allowHeader(allowed))
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+	}
+
 	if err == ErrNoHandlerFound {
+		if r.NotFound != nil {
+			r.dispatch(r.NotFound, w, request)
+			return
+		}
 		w.WriteHeader(http.StatusNotFound)
 		return
 	}
 
 	if err == errPathMatchMethodMismatch {
+		if allowed := r.allowedMethods(segments); len(allowed) > 0 {
+			w.Header().Set("Allow", allowHeader(allowed))
+		}
+		if r.MethodNotAllowed != nil {
+			r.dispatch(r.MethodNotAllowed, w, request)
+			return
+		}
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
@@ -70,91 +122,74 @@ func (r Router) ServeHTTP(w http.ResponseWriter, request *http.Request) {
 	w.WriteHeader(http.StatusInternalServerError)
 }
 
-func cleanPath(path string) string {
-	return strings.TrimRight(deduplicateDelimiter(path), "/")
+// dispatch invokes handler, recovering from a panic into r.PanicHandler when
+// one is installed. With no PanicHandler set, a panic propagates unchanged
+// to net/http's default recovery, preserving today's behavior.
+func (r Router) dispatch(handler http.HandlerFunc, w http.ResponseWriter, request *http.Request) {
+	if r.PanicHandler != nil {
+		defer func() {
+			if rec := recover(); rec != nil {
+				r.PanicHandler(w, request, rec)
+			}
+		}()
+	}
+	handler(w, request)
 }
 
-func deduplicateDelimiter(path string) string {
-	path = strings.ReplaceAll(path, "//", "/")
-	if strings.Count(path, "//") > 0 {
-		return deduplicateDelimiter(path)
-	}
-	return path
+// allowHeader renders methods as a deterministic, comma-joined Allow header value.
+func allowHeader(methods []string) string {
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
 }
 
+// determineHandler walks the trie for the request's method, and falls back
+// to scanning the other methods' tries to tell an unmatched path apart from
+// a path that matched under a different method.
 func (r *Router) determineHandler(request *http.Request) (http.HandlerFunc, map[string]string, error) {
-	var pathMatched bool
-	for _, rule := range r.routes {
-		err, parameters := r.match(*request, rule)
-
-		if err == nil {
-			return rule.Handler, parameters, nil
-		}
-
-		if err == errMethodMismatch {
-			pathMatched = true
+	segments := splitPath(cleanPath(request.URL.Path))
+
+	if root, ok := r.trees[request.Method]; ok {
+		params := getParams()
+		matched := root.match(segments, params)
+		if matched != nil && matched.handler != nil {
+			result := paramsToMap(*params)
+			putParams(params)
+			return matched.handler, result, nil
 		}
-
-	}
-
-	if pathMatched {
-		return nil, nil, errPathMatchMethodMismatch
+		putParams(params)
 	}
 
-	return nil, nil, ErrNoHandlerFound
-}
-
-func (r *Router) match(request http.Request, rule routingRule) (error, map[string]string) {
-	requestPath := cleanPath(request.URL.Path)
-	splitRulePattern := strings.Split(rule.Pattern, pathDelimiter)[1:]
-	splitRequestPath := strings.Split(requestPath, pathDelimiter)[1:]
-
-	parameters := make(map[string]string, strings.Count(requestPath, ":"))
-
-	for index, value := range splitRequestPath {
-		if len(splitRulePattern) < index+1 {
-			return errPathMismatch, nil
-		}
-
-		pattern := splitRulePattern[index]
-
-		if pattern[0] == ':' {
-			parameters[pattern[1:]] = value
+	for method, root := range r.trees {
+		if method == request.Method {
 			continue
 		}
 
-		if value != pattern {
-			return errPathMismatch, nil
-		}
-	}
+		params := getParams()
+		matched := root.match(segments, params)
+		putParams(params)
 
-	if request.Method != rule.Method {
-		return errMethodMismatch, nil
+		if matched != nil && matched.handler != nil {
+			return nil, nil, errPathMatchMethodMismatch
+		}
 	}
 
-	return nil, parameters
+	return nil, nil, ErrNoHandlerFound
 }
 
+// Register adds a new route for method and path, returning
+// ErrPathAlreadyRegistered if it collides with a handler already registered
+// at the same method and path.
 func (r *Router) Register(method string, path string, handler http.HandlerFunc) error {
-	if r.isPathAlreadyRegistered(path) {
-		return ErrPathAlreadyRegistered
+	if r.trees == nil {
+		r.trees = make(map[string]*node)
 	}
 
-	newRoute := routingRule{
-		Pattern: path,
-		Method:  method,
-		Handler: handler,
+	root, ok := r.trees[method]
+	if !ok {
+		root = &node{}
+		r.trees[method] = root
 	}
 
-	r.routes = append(r.routes, newRoute)
-	return nil
-}
-
-func (r *Router) isPathAlreadyRegistered(path string) bool {
-	for _, route := range r.routes {
-		if route.Pattern == path {
-			return true
-		}
-	}
-	return false
+	wrapped := wrapMiddleware(handler, r.middleware)
+	return root.insert(splitPath(cleanPath(r.prefix+path)), wrapped)
 }
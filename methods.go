@@ -0,0 +1,61 @@
+package main
+
+import "net/http"
+
+// Handle registers handler for method and path. It is the method used
+// internally by the verb-specific helpers below.
+func (r *Router) Handle(method string, path string, handler http.HandlerFunc) error {
+	return r.Register(method, path, handler)
+}
+
+// GET registers handler for GET requests to path.
+func (r *Router) GET(path string, handler http.HandlerFunc) error {
+	return r.Handle(http.MethodGet, path, handler)
+}
+
+// POST registers handler for POST requests to path.
+func (r *Router) POST(path string, handler http.HandlerFunc) error {
+	return r.Handle(http.MethodPost, path, handler)
+}
+
+// PUT registers handler for PUT requests to path.
+func (r *Router) PUT(path string, handler http.HandlerFunc) error {
+	return r.Handle(http.MethodPut, path, handler)
+}
+
+// PATCH registers handler for PATCH requests to path.
+func (r *Router) PATCH(path string, handler http.HandlerFunc) error {
+	return r.Handle(http.MethodPatch, path, handler)
+}
+
+// DELETE registers handler for DELETE requests to path.
+func (r *Router) DELETE(path string, handler http.HandlerFunc) error {
+	return r.Handle(http.MethodDelete, path, handler)
+}
+
+// HEAD registers handler for HEAD requests to path.
+func (r *Router) HEAD(path string, handler http.HandlerFunc) error {
+	return r.Handle(http.MethodHead, path, handler)
+}
+
+// OPTIONS registers handler for OPTIONS requests to path.
+func (r *Router) OPTIONS(path string, handler http.HandlerFunc) error {
+	return r.Handle(http.MethodOptions, path, handler)
+}
+
+// allowedMethods returns, in no particular order, the HTTP methods that have
+// a route registered matching segments, used to build the Allow header on a
+// 405 response and to auto-answer OPTIONS requests.
+func (r *Router) allowedMethods(segments []string) []string {
+	var methods []string
+	for method, root := range r.trees {
+		params := getParams()
+		matched := root.match(segments, params)
+		putParams(params)
+
+		if matched != nil && matched.handler != nil {
+			methods = append(methods, method)
+		}
+	}
+	return methods
+}
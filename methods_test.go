@@ -0,0 +1,92 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVerbHelpersRegisterExpectedMethod(t *testing.T) {
+	router := NewRouter()
+	if err := router.GET("/widgets", noopHandler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := router.POST("/widgets", noopHandler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := router.trees[http.MethodGet]; !ok {
+		t.Fatal("expected a GET tree to exist")
+	}
+	if _, ok := router.trees[http.MethodPost]; !ok {
+		t.Fatal("expected a POST tree to exist")
+	}
+}
+
+func TestServeHTTPSetsAllowHeaderOn405(t *testing.T) {
+	router := NewRouter()
+	router.GET("/widgets", noopHandler)
+	router.POST("/widgets", noopHandler)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodDelete, "/widgets", nil)
+	router.ServeHTTP(w, r)
+
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("expected 405, got %d", w.Code)
+	}
+
+	allow := w.Header().Get("Allow")
+	if !hasMethod(allow, http.MethodGet) || !hasMethod(allow, http.MethodPost) {
+		t.Fatalf("expected Allow header to list GET and POST, got %q", allow)
+	}
+}
+
+func TestServeHTTPAutoRespondsToOptions(t *testing.T) {
+	router := NewRouter()
+	router.GET("/widgets", noopHandler)
+	router.POST("/widgets", noopHandler)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodOptions, "/widgets", nil)
+	router.ServeHTTP(w, r)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	allow := w.Header().Get("Allow")
+	if !hasMethod(allow, http.MethodGet) || !hasMethod(allow, http.MethodPost) {
+		t.Fatalf("expected Allow header to list GET and POST, got %q", allow)
+	}
+}
+
+func TestServeHTTPUsesExplicitOptionsHandler(t *testing.T) {
+	router := NewRouter()
+	called := false
+	router.OPTIONS("/widgets", func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodOptions, "/widgets", nil)
+	router.ServeHTTP(w, r)
+
+	if !called {
+		t.Fatal("expected the registered OPTIONS handler to run")
+	}
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("expected 204, got %d", w.Code)
+	}
+}
+
+func hasMethod(allow, method string) bool {
+	for _, m := range strings.Split(allow, ", ") {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
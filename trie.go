@@ -0,0 +1,237 @@
+package main
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Param is a single named path parameter captured while matching a route.
+type Param struct {
+	Key   string
+	Value string
+}
+
+// Params is a set of parameters captured during a single route match. Every
+// lookup takes one from paramsPool and returns it afterwards, so the backing
+// slice is reused across requests.
+type Params []Param
+
+var paramsPool = sync.Pool{
+	New: func() interface{} {
+		p := make(Params, 0, 8)
+		return &p
+	},
+}
+
+func getParams() *Params {
+	p := paramsPool.Get().(*Params)
+	*p = (*p)[:0]
+	return p
+}
+
+func putParams(p *Params) {
+	paramsPool.Put(p)
+}
+
+// paramsToMap converts the captured parameters to the map[string]string
+// shape exposed through ParametersKey.
+func paramsToMap(params Params) map[string]string {
+	if len(params) == 0 {
+		return map[string]string{}
+	}
+	m := make(map[string]string, len(params))
+	for _, p := range params {
+		m[p.Key] = p.Value
+	}
+	return m
+}
+
+// constrainedChild is a `{name:pattern}` segment, matched by regex rather
+// than by exact string or by catching any value.
+type constrainedChild struct {
+	pattern *regexp.Regexp
+	names   []string
+	child   *node
+}
+
+// node is a single segment of a per-method routing trie. Each node owns a
+// map of static children, any number of regex-constrained children, at most
+// one `:name` param child and at most one `*name` catch-all child.
+type node struct {
+	segment string
+
+	static       map[string]*node
+	constrained  []*constrainedChild
+	paramChild   *node
+	paramName    string
+	catchAll     *node
+	catchAllName string
+
+	handler http.HandlerFunc
+}
+
+// splitPath splits an already-cleaned path into its non-empty segments.
+func splitPath(path string) []string {
+	if path == "" || path == pathDelimiter {
+		return nil
+	}
+	return strings.Split(strings.TrimPrefix(path, pathDelimiter), pathDelimiter)
+}
+
+// findConstrained returns the constrained child already registered with the
+// given compiled regex source, if any, so that two routes sharing the same
+// constraint at the same position reuse one child node.
+func (n *node) findConstrained(patternSource string) *constrainedChild {
+	for _, cc := range n.constrained {
+		if cc.pattern.String() == patternSource {
+			return cc
+		}
+	}
+	return nil
+}
+
+// insert registers handler for the given path segments, creating
+// intermediate nodes as needed. It rejects a catch-all segment that is not
+// last, a wildcard segment whose name conflicts with a wildcard already
+// registered at the same position, and a `:name` param registered at the
+// same position as a `*name` catch-all, in either order (match tries the
+// param child first, so the param route would shadow the catch-all for
+// every path it can match).
+func (n *node) insert(segments []string, handler http.HandlerFunc) error {
+	cur := n
+	for i, seg := range segments {
+		if seg == "" {
+			continue
+		}
+
+		switch seg[0] {
+		case ':':
+			if cur.catchAll != nil {
+				return ErrConflictingWildcard
+			}
+			name := seg[1:]
+			if cur.paramChild == nil {
+				cur.paramChild = &node{paramName: name}
+			} else if cur.paramChild.paramName != name {
+				return ErrConflictingWildcard
+			}
+			cur = cur.paramChild
+		case '*':
+			if i != len(segments)-1 {
+				return ErrCatchAllNotLast
+			}
+			if cur.paramChild != nil {
+				return ErrConflictingWildcard
+			}
+			name := seg[1:]
+			if cur.catchAll == nil {
+				cur.catchAll = &node{catchAllName: name}
+			} else if cur.catchAll.catchAllName != name {
+				return ErrConflictingWildcard
+			}
+			cur = cur.catchAll
+		default:
+			if strings.Contains(seg, "{") {
+				pattern, names, err := compileConstrainedSegment(seg)
+				if err != nil {
+					return err
+				}
+
+				cc := cur.findConstrained(pattern.String())
+				if cc == nil {
+					cc = &constrainedChild{pattern: pattern, names: names, child: &node{segment: seg}}
+					cur.constrained = append(cur.constrained, cc)
+				}
+				cur = cc.child
+				continue
+			}
+
+			if cur.static == nil {
+				cur.static = make(map[string]*node)
+			}
+			child, ok := cur.static[seg]
+			if !ok {
+				child = &node{segment: seg}
+				cur.static[seg] = child
+			}
+			cur = child
+		}
+	}
+
+	if cur.handler != nil {
+		return ErrPathAlreadyRegistered
+	}
+	cur.handler = handler
+	return nil
+}
+
+// match walks the trie for the given path segments, appending any captured
+// wildcard values to params. It returns the matched node, or nil if no
+// route matches. The common static-only case performs no allocations.
+func (n *node) match(segments []string, params *Params) *node {
+	return n.matchFrom(segments, 0, params)
+}
+
+// matchFrom tries, in priority order (static, regex-constrained, param,
+// catch-all), every child that could consume segments[i:]. If a choice
+// leads to a dead end further down the trie, it backtracks and tries the
+// next one — two non-conflicting routes that only diverge below the
+// current depth (e.g. a static `/users/admin` alongside a param
+// `/users/:id/posts`) must both remain reachable.
+func (n *node) matchFrom(segments []string, i int, params *Params) *node {
+	for i < len(segments) && segments[i] == "" {
+		i++
+	}
+
+	if i >= len(segments) {
+		if n.handler != nil {
+			return n
+		}
+		return nil
+	}
+
+	seg := segments[i]
+
+	if n.static != nil {
+		if child, ok := n.static[seg]; ok {
+			if matched := child.matchFrom(segments, i+1, params); matched != nil {
+				return matched
+			}
+		}
+	}
+
+	for _, cc := range n.constrained {
+		m := cc.pattern.FindStringSubmatch(seg)
+		if m == nil {
+			continue
+		}
+
+		mark := len(*params)
+		for j, name := range cc.names {
+			*params = append(*params, Param{Key: name, Value: m[j+1]})
+		}
+		if matched := cc.child.matchFrom(segments, i+1, params); matched != nil {
+			return matched
+		}
+		*params = (*params)[:mark]
+	}
+
+	if n.paramChild != nil {
+		mark := len(*params)
+		*params = append(*params, Param{Key: n.paramChild.paramName, Value: seg})
+		if matched := n.paramChild.matchFrom(segments, i+1, params); matched != nil {
+			return matched
+		}
+		*params = (*params)[:mark]
+	}
+
+	if n.catchAll != nil && n.catchAll.handler != nil {
+		rest := strings.Join(segments[i:], pathDelimiter)
+		*params = append(*params, Param{Key: n.catchAll.catchAllName, Value: rest})
+		return n.catchAll
+	}
+
+	return nil
+}
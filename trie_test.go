@@ -0,0 +1,138 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func noopHandler(w http.ResponseWriter, r *http.Request) {}
+
+func TestNodeInsertRejectsConflictingWildcardNames(t *testing.T) {
+	root := &node{}
+	if err := root.insert(splitPath("/users/:id"), noopHandler); err != nil {
+		t.Fatalf("unexpected error registering /users/:id: %v", err)
+	}
+	if err := root.insert(splitPath("/users/:name"), noopHandler); err != ErrConflictingWildcard {
+		t.Fatalf("expected ErrConflictingWildcard, got %v", err)
+	}
+}
+
+func TestNodeInsertRejectsCatchAllNotLast(t *testing.T) {
+	root := &node{}
+	if err := root.insert(splitPath("/files/*rest/more"), noopHandler); err != ErrCatchAllNotLast {
+		t.Fatalf("expected ErrCatchAllNotLast, got %v", err)
+	}
+}
+
+func TestNodeInsertRejectsParamCatchAllConflict(t *testing.T) {
+	cases := []struct {
+		name   string
+		first  string
+		second string
+	}{
+		{"param_then_catch_all", "/x/:id", "/x/*rest"},
+		{"catch_all_then_param", "/x/*rest", "/x/:id"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			root := &node{}
+			if err := root.insert(splitPath(c.first), noopHandler); err != nil {
+				t.Fatalf("unexpected error registering %s: %v", c.first, err)
+			}
+			if err := root.insert(splitPath(c.second), noopHandler); err != ErrConflictingWildcard {
+				t.Fatalf("expected ErrConflictingWildcard registering %s after %s, got %v", c.second, c.first, err)
+			}
+		})
+	}
+}
+
+func TestNodeMatchCatchAllSwallowsRemainder(t *testing.T) {
+	root := &node{}
+	if err := root.insert(splitPath("/files/*rest"), noopHandler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	params := getParams()
+	defer putParams(params)
+
+	matched := root.match(splitPath("/files/a/b/c"), params)
+	if matched == nil || matched.handler == nil {
+		t.Fatal("expected catch-all to match")
+	}
+	if len(*params) != 1 || (*params)[0].Key != "rest" || (*params)[0].Value != "a/b/c" {
+		t.Fatalf("unexpected params: %+v", *params)
+	}
+}
+
+func TestNodeMatchBacktracksPastStaticDeadEndToParam(t *testing.T) {
+	root := &node{}
+	if err := root.insert(splitPath("/users/admin"), noopHandler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := root.insert(splitPath("/users/:id/posts"), noopHandler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	params := getParams()
+	defer putParams(params)
+
+	matched := root.match(splitPath("/users/admin/posts"), params)
+	if matched == nil || matched.handler == nil {
+		t.Fatal("expected the param route to match after the static route dead-ends")
+	}
+	if len(*params) != 1 || (*params)[0].Key != "id" || (*params)[0].Value != "admin" {
+		t.Fatalf("unexpected params: %+v", *params)
+	}
+
+	adminParams := getParams()
+	defer putParams(adminParams)
+
+	matchedAdmin := root.match(splitPath("/users/admin"), adminParams)
+	if matchedAdmin == nil || matchedAdmin.handler == nil {
+		t.Fatal("expected the static /users/admin route to still match on its own")
+	}
+}
+
+func TestRouterBacktracksPastStaticDeadEndToParam(t *testing.T) {
+	router := NewRouter()
+	router.GET("/users/admin", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+	router.GET("/users/:id/posts", func(w http.ResponseWriter, r *http.Request) {
+		params := r.Context().Value(ParametersKey).(map[string]string)
+		if params["id"] != "admin" {
+			t.Errorf("expected id=admin, got %q", params["id"])
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/admin/posts", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+}
+
+func TestNodeMatchStaticTakesPriorityOverParam(t *testing.T) {
+	root := &node{}
+	if err := root.insert(splitPath("/users/:id"), noopHandler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := root.insert(splitPath("/users/me"), noopHandler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	params := getParams()
+	defer putParams(params)
+
+	matched := root.match(splitPath("/users/me"), params)
+	if matched == nil || matched.handler == nil {
+		t.Fatal("expected a match")
+	}
+	if len(*params) != 0 {
+		t.Fatalf("expected the static route to win with no captured params, got %+v", *params)
+	}
+}